@@ -0,0 +1,88 @@
+package bind
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type searchParams struct {
+	Query string `query:"q,required"`
+	Page  int    `query:"page,min=1"`
+}
+
+func TestBindFromQueryString(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/search?q=hello&page=2", nil)
+
+	var got searchParams
+	if err := Bind(r, &got); err != nil {
+		t.Fatalf("Bind: %v", err)
+	}
+
+	if got.Query != "hello" || got.Page != 2 {
+		t.Errorf("got %+v, want {Query:hello Page:2}", got)
+	}
+}
+
+func TestBindFromFormBody(t *testing.T) {
+	body := strings.NewReader(url.Values{"q": {"hello"}, "page": {"3"}}.Encode())
+	r := httptest.NewRequest(http.MethodPost, "/search", body)
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var got searchParams
+	if err := Bind(r, &got); err != nil {
+		t.Fatalf("Bind: %v", err)
+	}
+
+	if got.Query != "hello" || got.Page != 3 {
+		t.Errorf("got %+v, want {Query:hello Page:3}", got)
+	}
+}
+
+func TestMiddlewareInvokesHandlerOnSuccess(t *testing.T) {
+	called := false
+	h := Middleware(func(w http.ResponseWriter, r *http.Request, p searchParams) {
+		called = true
+		if p.Query != "hello" {
+			t.Errorf("Query = %q, want %q", p.Query, "hello")
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/search?q=hello&page=1", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if !called {
+		t.Fatal("handler was not invoked")
+	}
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestMiddlewareWritesValidationErrorAsJSON(t *testing.T) {
+	h := Middleware(func(w http.ResponseWriter, r *http.Request, p searchParams) {
+		t.Fatal("handler should not be invoked when binding fails validation")
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/search?page=0", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	var resp errorResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding response body: %v", err)
+	}
+
+	if _, ok := resp.Fields["q"]; !ok {
+		t.Errorf("Fields = %v, want a %q entry for the missing required query param", resp.Fields, "q")
+	}
+}
@@ -0,0 +1,156 @@
+package queryparams
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// fieldMeta is the pre-parsed metadata for one struct field: its index
+// (direct v.Field(index) access, no name lookup), its tag name, and the
+// omitzero/style/validation options parsed out of its `query` tag. Building
+// this once per struct type means Marshal/Unmarshal no longer re-walk
+// NumField() or re-parse tags on every call.
+type fieldMeta struct {
+	index         int
+	field         reflect.StructField
+	tagName       string
+	omitzero      bool
+	styleOverride *Style
+	format        CollectionFormat
+	rule          fieldRule
+}
+
+// marshalName is the query key to use when encoding this field, applying
+// Marshal's default-name convention (full lowercase) if the tag gave none.
+func (fm fieldMeta) marshalName() string {
+	if fm.tagName != "" {
+		return fm.tagName
+	}
+
+	return strings.ToLower(fm.field.Name)
+}
+
+// unmarshalName is the query key to use when decoding this field, applying
+// Unmarshal's default-name convention (lowercase first letter only, matching
+// the original hand-written loop) if the tag gave none.
+func (fm fieldMeta) unmarshalName() string {
+	if fm.tagName != "" {
+		return fm.tagName
+	}
+
+	return strings.ToLower(fm.field.Name[:1]) + fm.field.Name[1:]
+}
+
+// typeMeta is the cached metadata for one struct type.
+type typeMeta struct {
+	fields []fieldMeta
+}
+
+// typeMetaCache memoizes typeMeta per reflect.Type. It's a sync.Map rather
+// than a mutex-guarded map since lookups vastly outnumber the one-time
+// build per type, which is exactly what sync.Map is tuned for.
+var typeMetaCache sync.Map // reflect.Type -> *typeMeta
+
+// splitTagOptions splits a `query` struct tag into its comma-separated parts
+// the way parseTag/parseFieldRule/parseCollectionFormat all need: plainly on
+// every comma, except inside a "pattern=" option, where a comma nested inside
+// a "{...}" repetition quantifier (e.g. "{2,4}") doesn't end the option —
+// only a comma at brace-depth zero does. That lets pattern= appear anywhere
+// in the tag, with further options still parsed after it, e.g.
+// "code,pattern=^[A-Z]{2,4}$,omitzero" yields ["code", "pattern=^[A-Z]{2,4}$",
+// "omitzero"] rather than swallowing ",omitzero" into the regex.
+func splitTagOptions(tag string) []string {
+	var parts []string
+
+	for tag != "" {
+		if strings.HasPrefix(tag, "pattern=") {
+			end := patternOptionEnd(tag)
+			parts = append(parts, tag[:end])
+
+			if end == len(tag) {
+				break
+			}
+
+			tag = tag[end+1:] // skip the terminating comma
+			continue
+		}
+
+		if idx := strings.IndexByte(tag, ','); idx >= 0 {
+			parts = append(parts, tag[:idx])
+			tag = tag[idx+1:]
+			continue
+		}
+
+		parts = append(parts, tag)
+		break
+	}
+
+	return parts
+}
+
+// patternOptionEnd returns the index in tag (which starts with "pattern=")
+// where that option ends: either the first brace-depth-zero comma, or
+// len(tag) if the regex runs to the end of the tag.
+func patternOptionEnd(tag string) int {
+	depth := 0
+
+	for i := 0; i < len(tag); i++ {
+		switch tag[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return len(tag)
+}
+
+func metaFor(t reflect.Type) (*typeMeta, error) {
+	if cached, ok := typeMetaCache.Load(t); ok {
+		return cached.(*typeMeta), nil
+	}
+
+	fields := make([]fieldMeta, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get(structTagName)
+		name, omitzero, styleOverride := parseTag(tag)
+
+		if name == "-" {
+			continue
+		}
+
+		rule, err := parseFieldRule(tag)
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", sf.Name, err)
+		}
+
+		format, err := parseCollectionFormat(tag)
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", sf.Name, err)
+		}
+
+		fields = append(fields, fieldMeta{
+			index:         i,
+			field:         sf,
+			tagName:       name,
+			omitzero:      omitzero,
+			styleOverride: styleOverride,
+			format:        format,
+			rule:          rule,
+		})
+	}
+
+	meta := &typeMeta{fields: fields}
+	typeMetaCache.Store(t, meta)
+	return meta, nil
+}
@@ -0,0 +1,352 @@
+// Command queryparams-gen scans a Go package for structs with `query`
+// struct tags and emits type-specialized MarshalQueryParams/
+// UnmarshalQueryParams methods that satisfy queryparams.QueryParamsMarshaler
+// and queryparams.QueryParamsUnmarshaler without reflection at request time.
+//
+// It only generates direct field access for string, the sized ints/uints/
+// floats, bool, and time.Time fields. A struct with any other field kind
+// (nested structs, maps, slices, custom Marshaler/Unmarshaler types) is left
+// alone: queryparams.Marshal/Unmarshal already handle it correctly via
+// reflection, and a generated method delegating back to them would satisfy
+// QueryParamsMarshaler/QueryParamsUnmarshaler on the very type Marshal checks
+// for that interface on first, recursing forever.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+func main() {
+	dir := flag.String("dir", ".", "directory to scan for query-tagged structs")
+	out := flag.String("out", "queryparams_gen.go", "output file name, written into -dir")
+	flag.Parse()
+
+	if err := run(*dir, *out); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// genField is one field of a generated struct: enough to emit both a direct
+// accessor (fast path) and a url.Values key.
+type genField struct {
+	GoName   string
+	Key      string
+	OmitZero bool
+	Kind     string // "string", "int", "uint", "float", "bool", "time"
+	Bits     int
+	TypeName string // exact Go type, e.g. "int32", used to cast decoded values back
+}
+
+// genStruct is one query-tagged struct found while scanning the package.
+// Simple is false if any field couldn't be classified into genField, in
+// which case run generates no methods for the struct at all, leaving it to
+// queryparams.Marshal/Unmarshal's reflection path.
+type genStruct struct {
+	Name   string
+	Fields []genField
+	Simple bool
+}
+
+func run(dir, out string) error {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, nil, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+
+	var pkgName string
+	var structs []genStruct
+
+	for name, pkg := range pkgs {
+		if strings.HasSuffix(name, "_test") {
+			continue
+		}
+		pkgName = name
+
+		for _, file := range pkg.Files {
+			ast.Inspect(file, func(n ast.Node) bool {
+				ts, ok := n.(*ast.TypeSpec)
+				if !ok {
+					return true
+				}
+
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok {
+					return true
+				}
+
+				if gs, ok := parseStruct(ts.Name.Name, st); ok {
+					structs = append(structs, gs)
+				}
+
+				return true
+			})
+		}
+	}
+
+	if len(structs) == 0 {
+		return fmt.Errorf("no query-tagged structs found in %s", dir)
+	}
+
+	simple := structs[:0]
+	for _, gs := range structs {
+		if gs.Simple {
+			simple = append(simple, gs)
+		} else {
+			fmt.Fprintf(os.Stderr, "queryparams-gen: skipping %s: has a field queryparams-gen can't specialize (nested struct/map/slice/custom type)\n", gs.Name)
+		}
+	}
+
+	if len(simple) == 0 {
+		return fmt.Errorf("no struct in %s has only queryparams-gen-supported field types", dir)
+	}
+
+	sort.Slice(simple, func(i, j int) bool { return simple[i].Name < simple[j].Name })
+
+	src, err := render(pkgName, simple)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, out), src, 0o644)
+}
+
+// parseStruct extracts the query-tagged fields of a struct type. The second
+// return value is false if the struct has no `query` tags at all, meaning
+// it should be skipped entirely rather than generated with zero fields.
+func parseStruct(name string, st *ast.StructType) (genStruct, bool) {
+	gs := genStruct{Name: name, Simple: true}
+	tagged := false
+
+	for _, field := range st.Fields.List {
+		if field.Tag == nil || len(field.Names) != 1 {
+			continue
+		}
+
+		tag := reflect.StructTag(strings.Trim(field.Tag.Value, "`")).Get("query")
+		if tag == "" {
+			continue
+		}
+
+		tagged = true
+		parts := strings.Split(tag, ",")
+		key := parts[0]
+		if key == "-" {
+			continue
+		}
+		if key == "" {
+			key = strings.ToLower(field.Names[0].Name)
+		}
+
+		omitzero := false
+		for _, p := range parts[1:] {
+			if p == "omitzero" {
+				omitzero = true
+			}
+		}
+
+		kind, bits, typeName, ok := classifyType(field.Type)
+		if !ok {
+			gs.Simple = false
+			continue
+		}
+
+		gs.Fields = append(gs.Fields, genField{
+			GoName:   field.Names[0].Name,
+			Key:      key,
+			OmitZero: omitzero,
+			Kind:     kind,
+			Bits:     bits,
+			TypeName: typeName,
+		})
+	}
+
+	return gs, tagged
+}
+
+func classifyType(expr ast.Expr) (kind string, bits int, typeName string, ok bool) {
+	sel, isSelector := expr.(*ast.SelectorExpr)
+	if isSelector {
+		if pkgIdent, ok := sel.X.(*ast.Ident); ok && pkgIdent.Name == "time" && sel.Sel.Name == "Time" {
+			return "time", 0, "time.Time", true
+		}
+		return "", 0, "", false
+	}
+
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return "", 0, "", false
+	}
+
+	switch ident.Name {
+	case "string":
+		return "string", 0, "string", true
+	case "int", "int8", "int16", "int32", "int64":
+		return "int", bitsOf(ident.Name), ident.Name, true
+	case "uint", "uint8", "uint16", "uint32", "uint64":
+		return "uint", bitsOf(ident.Name), ident.Name, true
+	case "float32", "float64":
+		return "float", bitsOf(ident.Name), ident.Name, true
+	case "bool":
+		return "bool", 0, "bool", true
+	default:
+		return "", 0, "", false
+	}
+}
+
+func bitsOf(name string) int {
+	switch name {
+	case "int8", "uint8":
+		return 8
+	case "int16", "uint16":
+		return 16
+	case "int32", "uint32", "float32":
+		return 32
+	default:
+		return 64
+	}
+}
+
+func render(pkgName string, structs []genStruct) ([]byte, error) {
+	var body bytes.Buffer
+	var usesStrconv, usesTime bool
+
+	for _, gs := range structs {
+		writeSimpleMarshal(&body, gs)
+		writeSimpleUnmarshal(&body, gs)
+
+		for _, f := range gs.Fields {
+			if f.Kind == "time" {
+				usesTime = true
+			} else {
+				usesStrconv = true
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintln(&buf, "// Code generated by queryparams-gen. DO NOT EDIT.")
+	fmt.Fprintf(&buf, "package %s\n\n", pkgName)
+	fmt.Fprintln(&buf, `import (`)
+	fmt.Fprintln(&buf, `	"net/url"`)
+	if usesStrconv {
+		fmt.Fprintln(&buf, `	"strconv"`)
+	}
+	if usesTime {
+		fmt.Fprintln(&buf, `	"time"`)
+	}
+	fmt.Fprintln(&buf, `)`)
+	fmt.Fprintln(&buf)
+	buf.Write(body.Bytes())
+
+	return format.Source(buf.Bytes())
+}
+
+func writeSimpleMarshal(buf *bytes.Buffer, gs genStruct) {
+	fmt.Fprintf(buf, "func (v %s) MarshalQueryParams() (url.Values, error) {\n", gs.Name)
+	fmt.Fprintln(buf, "\tuv := url.Values{}")
+
+	for _, f := range gs.Fields {
+		expr := encodeExpr(f)
+
+		if f.OmitZero {
+			fmt.Fprintf(buf, "\tif v.%s != %s {\n\t", f.GoName, zeroLiteral(f))
+		}
+
+		fmt.Fprintf(buf, "\tuv.Set(%q, %s)\n", f.Key, expr)
+
+		if f.OmitZero {
+			fmt.Fprintln(buf, "\t}")
+		}
+	}
+
+	fmt.Fprintln(buf, "\treturn uv, nil")
+	fmt.Fprintln(buf, "}")
+	fmt.Fprintln(buf)
+}
+
+func writeSimpleUnmarshal(buf *bytes.Buffer, gs genStruct) {
+	fmt.Fprintf(buf, "func (v *%s) UnmarshalQueryParams(values url.Values) error {\n", gs.Name)
+
+	for _, f := range gs.Fields {
+		fmt.Fprintf(buf, "\tif s := values.Get(%q); s != \"\" {\n", f.Key)
+		fmt.Fprintln(buf, "\t\t"+decodeStmt(f))
+		fmt.Fprintln(buf, "\t}")
+	}
+
+	fmt.Fprintln(buf, "\treturn nil")
+	fmt.Fprintln(buf, "}")
+	fmt.Fprintln(buf)
+}
+
+func zeroLiteral(f genField) string {
+	switch f.Kind {
+	case "string":
+		return `""`
+	case "bool":
+		return "false"
+	case "time":
+		return "(time.Time{})"
+	default:
+		return "0"
+	}
+}
+
+func encodeExpr(f genField) string {
+	switch f.Kind {
+	case "string":
+		return "v." + f.GoName
+	case "int":
+		return fmt.Sprintf("strconv.FormatInt(int64(v.%s), 10)", f.GoName)
+	case "uint":
+		return fmt.Sprintf("strconv.FormatUint(uint64(v.%s), 10)", f.GoName)
+	case "float":
+		return fmt.Sprintf("strconv.FormatFloat(float64(v.%s), 'f', -1, %d)", f.GoName, f.Bits)
+	case "bool":
+		return fmt.Sprintf("strconv.FormatBool(v.%s)", f.GoName)
+	case "time":
+		return fmt.Sprintf("v.%s.Format(time.RFC3339)", f.GoName)
+	default:
+		return "\"\""
+	}
+}
+
+func decodeStmt(f genField) string {
+	switch f.Kind {
+	case "string":
+		return fmt.Sprintf("v.%s = s", f.GoName)
+	case "int":
+		return fmt.Sprintf(
+			"n, err := strconv.ParseInt(s, 10, %d); if err != nil { return err }; v.%s = %s(n)",
+			f.Bits, f.GoName, f.TypeName,
+		)
+	case "uint":
+		return fmt.Sprintf(
+			"n, err := strconv.ParseUint(s, 10, %d); if err != nil { return err }; v.%s = %s(n)",
+			f.Bits, f.GoName, f.TypeName,
+		)
+	case "float":
+		return fmt.Sprintf(
+			"n, err := strconv.ParseFloat(s, %d); if err != nil { return err }; v.%s = %s(n)",
+			f.Bits, f.GoName, f.TypeName,
+		)
+	case "bool":
+		return fmt.Sprintf("b, err := strconv.ParseBool(s); if err != nil { return err }; v.%s = b", f.GoName)
+	case "time":
+		return fmt.Sprintf("t, err := time.Parse(time.RFC3339, s); if err != nil { return err }; v.%s = t", f.GoName)
+	default:
+		return ""
+	}
+}
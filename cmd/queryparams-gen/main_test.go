@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sourceFixture = `package widgets
+
+type Widget struct {
+	Name  string ` + "`query:\"name\"`" + `
+	Count int    ` + "`query:\"count,omitzero\"`" + `
+}
+
+type Compound struct {
+	Label string   ` + "`query:\"label\"`" + `
+	Tags  []string ` + "`query:\"tags\"`" + `
+}
+`
+
+func TestRunGeneratesSpecializedMethodsForSimpleStructs(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "widget.go"), []byte(sourceFixture), 0o644); err != nil {
+		t.Fatalf("writing fixture: %v", err)
+	}
+
+	const out = "widget_gen.go"
+	if err := run(dir, out); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	generated, err := os.ReadFile(filepath.Join(dir, out))
+	if err != nil {
+		t.Fatalf("reading generated file: %v", err)
+	}
+	src := string(generated)
+
+	if !strings.Contains(src, "func (v Widget) MarshalQueryParams() (url.Values, error) {") {
+		t.Errorf("generated code missing Widget.MarshalQueryParams:\n%s", src)
+	}
+	if !strings.Contains(src, "func (v *Widget) UnmarshalQueryParams(values url.Values) error {") {
+		t.Errorf("generated code missing Widget.UnmarshalQueryParams:\n%s", src)
+	}
+	if strings.Contains(src, "func (v Compound)") {
+		t.Errorf("generated code should skip Compound (has a slice field): %s", src)
+	}
+}
+
+func TestRunErrorsWhenNoStructHasQueryTags(t *testing.T) {
+	dir := t.TempDir()
+	src := "package empty\n\ntype Plain struct {\n\tName string\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "plain.go"), []byte(src), 0o644); err != nil {
+		t.Fatalf("writing fixture: %v", err)
+	}
+
+	if err := run(dir, "plain_gen.go"); err == nil {
+		t.Fatal("run() = nil error, want an error for a package with no query-tagged structs")
+	}
+}
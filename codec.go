@@ -0,0 +1,163 @@
+package queryparams
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Codec lets callers teach queryparams how to encode/decode a type it
+// doesn't own (uuid.UUID, net/netip.Addr, decimal.Decimal, a custom enum,
+// ...) without wrapping it in a Marshaler/Unmarshaler. Encode may return
+// more than one string for types that expand into a multi-valued query key.
+type Codec interface {
+	Encode(reflect.Value) ([]string, error)
+	Decode(reflect.Value, []string) error
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[reflect.Type]Codec{}
+)
+
+// Register installs a Codec for t, used by every Marshal/Unmarshal call from
+// then on unless overridden per-call via Options.Codecs. It's typically
+// called once from an init function.
+func Register(t reflect.Type, c Codec) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[t] = c
+}
+
+func lookupCodec(t reflect.Type, overrides map[reflect.Type]Codec) (Codec, bool) {
+	if c, ok := overrides[t]; ok {
+		return c, true
+	}
+
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	c, ok := registry[t]
+	return c, ok
+}
+
+// encodeScalar renders v to one or more query values, preferring a
+// registered or per-call Codec over the built-in kind switch in toString.
+func encodeScalar(v reflect.Value, codecs map[reflect.Type]Codec) ([]string, error) {
+	if c, ok := lookupCodec(v.Type(), codecs); ok {
+		return c.Encode(v)
+	}
+
+	s, err := toString(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{s}, nil
+}
+
+// decodeScalar is the Codec-aware counterpart of setFromString.
+func decodeScalar(v reflect.Value, strs []string, codecs map[reflect.Type]Codec) error {
+	if c, ok := lookupCodec(v.Type(), codecs); ok {
+		return c.Decode(v, strs)
+	}
+
+	if len(strs) == 0 {
+		return nil
+	}
+
+	return setFromString(v, strs[0])
+}
+
+// durationCodec encodes/decodes time.Duration via its text form ("1h30m")
+// instead of the raw integer nanosecond count the kind switch would produce.
+type durationCodec struct{}
+
+func (durationCodec) Encode(v reflect.Value) ([]string, error) {
+	return []string{v.Interface().(time.Duration).String()}, nil
+}
+
+func (durationCodec) Decode(v reflect.Value, strs []string) error {
+	if len(strs) == 0 {
+		return nil
+	}
+
+	d, err := time.ParseDuration(strs[0])
+	if err != nil {
+		return err
+	}
+
+	v.Set(reflect.ValueOf(d))
+	return nil
+}
+
+func init() {
+	Register(reflect.TypeOf(time.Duration(0)), durationCodec{})
+}
+
+// timeCodec encodes time.Time using the first of layouts and decodes by
+// trying each layout in turn, so one endpoint can format a timestamp
+// differently from another without changing the Go type.
+type timeCodec struct {
+	layouts []string
+}
+
+// TimeLayout returns a Codec for time.Time that encodes using layout and
+// decodes by trying layout and then, in order, fallbacks. Register it
+// globally or pass it via Options.Codecs to override a specific Unmarshal
+// call.
+func TimeLayout(layout string, fallbacks ...string) Codec {
+	return timeCodec{layouts: append([]string{layout}, fallbacks...)}
+}
+
+func (c timeCodec) Encode(v reflect.Value) ([]string, error) {
+	return []string{v.Interface().(time.Time).Format(c.layouts[0])}, nil
+}
+
+func (c timeCodec) Decode(v reflect.Value, strs []string) error {
+	if len(strs) == 0 {
+		return nil
+	}
+
+	var lastErr error
+	for _, layout := range c.layouts {
+		t, err := time.Parse(layout, strs[0])
+		if err == nil {
+			v.Set(reflect.ValueOf(t))
+			return nil
+		}
+
+		lastErr = err
+	}
+
+	return lastErr
+}
+
+// unixSecondsCodec encodes/decodes time.Time as a Unix timestamp in seconds,
+// e.g. for APIs that expect "updated_at=1700000000".
+type unixSecondsCodec struct{}
+
+// UnixSeconds returns a Codec for time.Time that represents it as a Unix
+// timestamp in seconds rather than an RFC3339 string.
+func UnixSeconds() Codec {
+	return unixSecondsCodec{}
+}
+
+func (unixSecondsCodec) Encode(v reflect.Value) ([]string, error) {
+	return []string{strconv.FormatInt(v.Interface().(time.Time).Unix(), 10)}, nil
+}
+
+func (unixSecondsCodec) Decode(v reflect.Value, strs []string) error {
+	if len(strs) == 0 {
+		return nil
+	}
+
+	sec, err := strconv.ParseInt(strs[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("parsing unix timestamp: %w", err)
+	}
+
+	v.Set(reflect.ValueOf(time.Unix(sec, 0)))
+	return nil
+}
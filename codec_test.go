@@ -0,0 +1,139 @@
+package queryparams
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// level is a custom enum type that doesn't implement Marshaler/Unmarshaler,
+// to exercise Register instead.
+type level int
+
+const (
+	levelLow level = iota
+	levelHigh
+)
+
+type levelCodec struct{}
+
+func (levelCodec) Encode(v reflect.Value) ([]string, error) {
+	if v.Interface().(level) == levelHigh {
+		return []string{"high"}, nil
+	}
+	return []string{"low"}, nil
+}
+
+func (levelCodec) Decode(v reflect.Value, strs []string) error {
+	if len(strs) == 0 {
+		return nil
+	}
+
+	switch strs[0] {
+	case "high":
+		v.Set(reflect.ValueOf(levelHigh))
+	case "low":
+		v.Set(reflect.ValueOf(levelLow))
+	default:
+		return fmt.Errorf("unknown level %q", strs[0])
+	}
+
+	return nil
+}
+
+type alertForm struct {
+	Severity level `query:"severity"`
+}
+
+func TestRegisteredCodecRoundTrip(t *testing.T) {
+	Register(reflect.TypeOf(level(0)), levelCodec{})
+
+	in := alertForm{Severity: levelHigh}
+	values, err := Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	if got, want := values.Get("severity"), "high"; got != want {
+		t.Errorf("severity = %q, want %q", got, want)
+	}
+
+	var out alertForm
+	if err := Unmarshal(values, &out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if out != in {
+		t.Errorf("round trip mismatch: in = %+v, out = %+v", in, out)
+	}
+}
+
+type durationForm struct {
+	Timeout time.Duration `query:"timeout"`
+}
+
+func TestDurationCodec(t *testing.T) {
+	in := durationForm{Timeout: 90 * time.Minute}
+
+	values, err := Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	if got, want := values.Get("timeout"), "1h30m0s"; got != want {
+		t.Errorf("timeout = %q, want %q", got, want)
+	}
+
+	var out durationForm
+	if err := Unmarshal(values, &out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if out.Timeout != in.Timeout {
+		t.Errorf("Timeout = %v, want %v", out.Timeout, in.Timeout)
+	}
+}
+
+type eventForm struct {
+	At time.Time `query:"at"`
+}
+
+func TestTimeLayoutCodecFallback(t *testing.T) {
+	codec := TimeLayout(time.RFC3339, "2006-01-02")
+	want := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	var out eventForm
+	err := UnmarshalWith(url.Values{"at": {"2024-03-01"}}, &out, Options{
+		Codecs: map[reflect.Type]Codec{timeType: codec},
+	})
+	if err != nil {
+		t.Fatalf("UnmarshalWith: %v", err)
+	}
+
+	if !out.At.Equal(want) {
+		t.Errorf("At = %v, want %v", out.At, want)
+	}
+}
+
+func TestPerCallCodecOverrideDoesNotLeakBetweenCalls(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	in := eventForm{At: ts}
+
+	unix, err := MarshalWith(in, Options{Codecs: map[reflect.Type]Codec{timeType: UnixSeconds()}})
+	if err != nil {
+		t.Fatalf("MarshalWith: %v", err)
+	}
+	if got, want := unix.Get("at"), "1704164645"; got != want {
+		t.Errorf("unix.at = %q, want %q", got, want)
+	}
+
+	rfc3339, err := Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if got, want := rfc3339.Get("at"), ts.Format(time.RFC3339); got != want {
+		t.Errorf("rfc3339.at = %q, want %q (override must not leak into the global default)", got, want)
+	}
+}
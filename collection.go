@@ -0,0 +1,119 @@
+package queryparams
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CollectionFormat controls how a scalar slice field is rendered into a
+// single query key, mirroring the OpenAPI "collectionFormat" values.
+type CollectionFormat int
+
+const (
+	// FormatMulti is the default: one repeated "k=v1&k=v2" pair per element.
+	FormatMulti CollectionFormat = iota
+	// FormatCSV joins elements with a comma into a single "k=v1,v2" value.
+	FormatCSV
+	// FormatSSV joins elements with a space into a single "k=v1 v2" value.
+	FormatSSV
+	// FormatPipes joins elements with a pipe into a single "k=v1|v2" value.
+	FormatPipes
+)
+
+// delimiter returns the join/split character for f, or false for FormatMulti,
+// which has no delimiter since each element gets its own key/value pair.
+func (f CollectionFormat) delimiter() (byte, bool) {
+	switch f {
+	case FormatCSV:
+		return ',', true
+	case FormatSSV:
+		return ' ', true
+	case FormatPipes:
+		return '|', true
+	default:
+		return 0, false
+	}
+}
+
+// parseCollectionFormat parses the "format=" option out of a `query` struct
+// tag, e.g. "tags,format=csv". Absent, it's FormatMulti.
+func parseCollectionFormat(tag string) (CollectionFormat, error) {
+	if tag == "" {
+		return FormatMulti, nil
+	}
+
+	for _, p := range splitTagOptions(tag)[1:] {
+		if !strings.HasPrefix(p, "format=") {
+			continue
+		}
+
+		switch strings.TrimPrefix(p, "format=") {
+		case "multi", "":
+			return FormatMulti, nil
+		case "csv":
+			return FormatCSV, nil
+		case "ssv":
+			return FormatSSV, nil
+		case "pipes":
+			return FormatPipes, nil
+		default:
+			return FormatMulti, fmt.Errorf("unknown format %q", p)
+		}
+	}
+
+	return FormatMulti, nil
+}
+
+// joinDelimited escapes delim and '\' in each element with a backslash, then
+// joins them with delim, so a value that itself contains the delimiter
+// round-trips through splitDelimited.
+func joinDelimited(strs []string, delim byte) string {
+	escaped := make([]string, len(strs))
+	for i, s := range strs {
+		escaped[i] = escapeDelimited(s, delim)
+	}
+
+	return strings.Join(escaped, string(delim))
+}
+
+func escapeDelimited(s string, delim byte) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '\\' || c == delim {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+
+	return b.String()
+}
+
+// splitDelimited reverses joinDelimited: it splits s on unescaped occurrences
+// of delim, unescaping "\<delim>" and "\\" back to a literal character. A
+// string with no unescaped delim at all yields a single-element slice, so
+// plain repeated-key values pass through unchanged.
+func splitDelimited(s string, delim byte) []string {
+	var out []string
+	var cur strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '\\' && i+1 < len(s) {
+			cur.WriteByte(s[i+1])
+			i++
+			continue
+		}
+
+		if c == delim {
+			out = append(out, cur.String())
+			cur.Reset()
+			continue
+		}
+
+		cur.WriteByte(c)
+	}
+
+	out = append(out, cur.String())
+	return out
+}
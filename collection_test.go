@@ -0,0 +1,139 @@
+package queryparams
+
+import (
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+type tagsForm struct {
+	Tags []string `query:"tags,format=csv"`
+}
+
+func TestCollectionFormatCSVRoundTrip(t *testing.T) {
+	in := tagsForm{Tags: []string{"a", "b", "c"}}
+
+	values, err := Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	if got, want := values.Get("tags"), "a,b,c"; got != want {
+		t.Errorf("tags = %q, want %q", got, want)
+	}
+
+	var out tagsForm
+	if err := Unmarshal(values, &out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("round trip mismatch: in = %+v, out = %+v", in, out)
+	}
+}
+
+func TestCollectionFormatCSVEscapesDelimiter(t *testing.T) {
+	in := tagsForm{Tags: []string{"a,b", `c\d`}}
+
+	values, err := Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var out tagsForm
+	if err := Unmarshal(values, &out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("round trip mismatch: in = %+v, out = %+v", in, out)
+	}
+}
+
+func TestCollectionFormatAcceptsMixedRepeatedAndDelimitedInput(t *testing.T) {
+	values := url.Values{"tags": {"a,b", "c"}}
+
+	var out tagsForm
+	if err := Unmarshal(values, &out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	want := []string{"a", "b", "c"}
+	if !reflect.DeepEqual(out.Tags, want) {
+		t.Errorf("Tags = %v, want %v", out.Tags, want)
+	}
+}
+
+// octet is a custom scalar type with no Marshaler/Unmarshaler of its own,
+// relying entirely on a registered Codec to be treated as scalar.
+type octet struct {
+	value byte
+}
+
+type octetCodec struct{}
+
+func (octetCodec) Encode(v reflect.Value) ([]string, error) {
+	return []string{strconv.Itoa(int(v.Interface().(octet).value))}, nil
+}
+
+func (octetCodec) Decode(v reflect.Value, strs []string) error {
+	if len(strs) == 0 {
+		return nil
+	}
+
+	n, err := strconv.Atoi(strs[0])
+	if err != nil {
+		return err
+	}
+
+	v.Set(reflect.ValueOf(octet{value: byte(n)}))
+	return nil
+}
+
+type ipForm struct {
+	Octets []octet `query:"octets,format=csv"`
+}
+
+func TestCollectionFormatHonorsCodecForSliceElements(t *testing.T) {
+	Register(reflect.TypeOf(octet{}), octetCodec{})
+
+	in := ipForm{Octets: []octet{{value: 10}, {value: 0}, {value: 0}, {value: 1}}}
+
+	values, err := Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	if got, want := values.Get("octets"), "10,0,0,1"; got != want {
+		t.Errorf("octets = %q, want %q (format=csv should apply to a codec-backed slice element)", got, want)
+	}
+	if _, indexed := values["octets[0]"]; indexed {
+		t.Errorf("octets[0] present in %v, want the codec-backed slice to use format=csv, not the indexed form", values)
+	}
+
+	var out ipForm
+	if err := Unmarshal(values, &out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("round trip mismatch: in = %+v, out = %+v", in, out)
+	}
+}
+
+func TestCollectionFormatMultiIsStillRepeatedKeys(t *testing.T) {
+	type multiForm struct {
+		Tags []string `query:"tags"`
+	}
+
+	in := multiForm{Tags: []string{"a", "b"}}
+	values, err := Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	if got := values["tags"]; !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("tags = %v, want repeated [a b]", got)
+	}
+}
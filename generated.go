@@ -0,0 +1,20 @@
+package queryparams
+
+import "net/url"
+
+// QueryParamsMarshaler is implemented by types that know how to encode
+// themselves directly into url.Values without reflection — typically
+// generated code (see cmd/queryparams-gen). When src implements it and no
+// per-call Options.Codecs override is given, Marshal/MarshalWith call it
+// directly instead of walking the struct via reflect.
+type QueryParamsMarshaler interface {
+	MarshalQueryParams() (url.Values, error)
+}
+
+// QueryParamsUnmarshaler is the decode-time counterpart of
+// QueryParamsMarshaler. Unmarshal/UnmarshalWith still run the usual
+// validation pass afterwards, since that isn't something the generator
+// produces yet.
+type QueryParamsUnmarshaler interface {
+	UnmarshalQueryParams(url.Values) error
+}
@@ -0,0 +1,88 @@
+package queryparams
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeGenerated hand-writes the shape queryparams-gen would produce: a
+// value-receiver MarshalQueryParams and a pointer-receiver
+// UnmarshalQueryParams, neither of which knows about Options.Codecs.
+type fakeGenerated struct {
+	Updated time.Time `query:"updated"`
+}
+
+func (v fakeGenerated) MarshalQueryParams() (url.Values, error) {
+	return url.Values{"updated": {v.Updated.UTC().Format(time.RFC3339)}}, nil
+}
+
+func (v *fakeGenerated) UnmarshalQueryParams(values url.Values) error {
+	s := values.Get("updated")
+	if s == "" {
+		return nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return err
+	}
+
+	v.Updated = parsed
+	return nil
+}
+
+func TestMarshalNilPointerReturnsError(t *testing.T) {
+	var p *fakeGenerated
+
+	_, err := Marshal(p)
+	if err == nil {
+		t.Fatal("Marshal(nil pointer) = nil error, want an error")
+	}
+}
+
+func TestMarshalWithUsesFastPathByDefault(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	values, err := Marshal(fakeGenerated{Updated: ts})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	if got, want := values.Get("updated"), ts.Format(time.RFC3339); got != want {
+		t.Errorf("updated = %q, want %q (fast path should win with no codec override)", got, want)
+	}
+}
+
+func TestMarshalWithCodecOverrideBypassesFastPath(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	values, err := MarshalWith(fakeGenerated{Updated: ts}, Options{
+		Codecs: map[reflect.Type]Codec{timeType: UnixSeconds()},
+	})
+	if err != nil {
+		t.Fatalf("MarshalWith: %v", err)
+	}
+
+	if got, want := values.Get("updated"), "1704164645"; got != want {
+		t.Errorf("updated = %q, want %q (codec override should fall back to reflection)", got, want)
+	}
+}
+
+func TestUnmarshalWithCodecOverrideBypassesFastPath(t *testing.T) {
+	values := url.Values{"updated": {"1704164645"}}
+
+	var out fakeGenerated
+	err := UnmarshalWith(values, &out, Options{
+		Codecs: map[reflect.Type]Codec{timeType: UnixSeconds()},
+	})
+	if err != nil {
+		t.Fatalf("UnmarshalWith: %v", err)
+	}
+
+	want := time.Unix(1704164645, 0)
+	if !out.Updated.Equal(want) {
+		t.Errorf("Updated = %v, want %v (codec override should fall back to reflection)", out.Updated, want)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/url"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -27,10 +28,61 @@ type Unmarshaler interface {
 	UnmarshalQueryParam(string) error
 }
 
-// Marshal turns any struct into url.Values according to `url` tags.
-// Supported field kinds: string, ints, uints, floats, bool, time.Time, slices of those.
+// Style controls how nested struct and map fields are flattened into query
+// keys.
+type Style int
+
+const (
+	// StyleDot renders nested keys as "address.city" (the default).
+	StyleDot Style = iota
+	// StyleBracket renders nested keys as "address[city]".
+	StyleBracket
+)
+
+// Options configures Marshal/Unmarshal behaviour beyond what struct tags can
+// express.
+type Options struct {
+	// Style is the nesting style used for a field's children unless its own
+	// tag overrides it with "style=dot" or "style=bracket". Defaults to
+	// StyleDot.
+	Style Style
+
+	// Codecs overrides the global codec registry for this call only, keyed
+	// by the Go type a Codec handles. Use it when the same type needs to be
+	// formatted differently across endpoints, e.g. time.Time as Unix
+	// seconds here and RFC3339 elsewhere.
+	Codecs map[reflect.Type]Codec
+}
+
+var (
+	marshalerType   = reflect.TypeOf((*Marshaler)(nil)).Elem()
+	unmarshalerType = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+	timeType        = reflect.TypeOf(time.Time{})
+)
+
+// Marshal turns any struct into url.Values according to `query` tags.
+// Supported field kinds: string, ints, uints, floats, bool, time.Time, nested
+// structs, map[string]T, and slices of any of those. It is equivalent to
+// MarshalWith(src, Options{}).
 func Marshal(src any) (url.Values, error) {
+	return MarshalWith(src, Options{})
+}
+
+// MarshalWith behaves like Marshal but lets the caller pick the default
+// nesting style for fields whose tag doesn't specify one.
+func MarshalWith(src any, opts Options) (url.Values, error) {
 	v := reflect.ValueOf(src)
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return nil, fmt.Errorf("expected struct but got nil %s", v.Type())
+	}
+
+	// A generated MarshalQueryParams bakes in fixed formatting (e.g. RFC3339
+	// for time.Time) and has no way to consult opts.Codecs, so a per-call
+	// override falls back to the reflection path, which does.
+	if m, ok := src.(QueryParamsMarshaler); ok && len(opts.Codecs) == 0 {
+		return m.MarshalQueryParams()
+	}
+
 	if v.Kind() == reflect.Pointer {
 		v = v.Elem()
 	}
@@ -40,128 +92,584 @@ func Marshal(src any) (url.Values, error) {
 	}
 
 	uv := url.Values{}
-	t := v.Type()
+	if err := marshalStruct(v, nil, opts.Style, uv, opts.Codecs); err != nil {
+		return uv, err
+	}
 
-	for i := 0; i < t.NumField(); i++ {
-		sf := t.Field(i)
-		name, omitzero := parseTag(sf.Tag.Get(structTagName))
+	return uv, nil
+}
 
-		if name == "-" {
+// marshalStruct walks the fields of v, encoding each into uv under a key
+// built from path plus the field's own name.
+func marshalStruct(v reflect.Value, path []string, style Style, uv url.Values, codecs map[reflect.Type]Codec) error {
+	meta, err := metaFor(v.Type())
+	if err != nil {
+		return err
+	}
+
+	for _, fm := range meta.fields {
+		fv := v.Field(fm.index)
+		if !fv.IsValid() || !fv.CanInterface() {
 			continue
 		}
 
-		if name == "" {
-			name = strings.ToLower(sf.Name)
+		if fm.omitzero && fv.IsZero() {
+			continue
 		}
 
-		fv := v.Field(i)
-		if !fv.IsValid() || !fv.CanInterface() {
-			continue
+		fieldStyle := style
+		if fm.styleOverride != nil {
+			fieldStyle = *fm.styleOverride
 		}
 
-		if omitzero && fv.IsZero() {
-			continue
+		if err := marshalValue(fv, withSegment(path, fm.marshalName()), fieldStyle, fm.format, uv, codecs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// marshalValue encodes fv under the key identified by path, recursing into
+// structs, maps, and slices as needed. format only affects fv when it's a
+// scalar slice; it's ignored otherwise.
+func marshalValue(fv reflect.Value, path []string, style Style, format CollectionFormat, uv url.Values, codecs map[reflect.Type]Codec) error {
+	if !fv.IsValid() || !fv.CanInterface() {
+		return nil
+	}
+
+	if _, ok := lookupCodec(fv.Type(), codecs); !ok {
+		if m, ok := implementsMarshaler(fv); ok {
+			s, err := m.MarshalQueryParam()
+			if err != nil {
+				return err
+			}
+
+			uv.Set(joinPath(path, style), s)
+			return nil
 		}
 
-		if fv.Kind() == reflect.Slice {
-			for j := 0; j < fv.Len(); j++ {
-				s, err := toString(fv.Index(j))
+		switch fv.Kind() {
+		case reflect.Struct:
+			if _, ok := fv.Interface().(time.Time); !ok {
+				return marshalStruct(fv, path, style, uv, codecs)
+			}
+
+		case reflect.Map:
+			return marshalMap(fv, path, style, uv, codecs)
+
+		case reflect.Slice:
+			return marshalSlice(fv, path, style, format, uv, codecs)
+		}
+	}
+
+	strs, err := encodeScalar(fv, codecs)
+	if err != nil {
+		return err
+	}
+
+	key := joinPath(path, style)
+	for _, s := range strs {
+		uv.Add(key, s)
+	}
+
+	return nil
+}
+
+// marshalMap encodes a map[string]T field, one key per map entry, in sorted
+// order so output is deterministic.
+func marshalMap(fv reflect.Value, path []string, style Style, uv url.Values, codecs map[reflect.Type]Codec) error {
+	if fv.Type().Key().Kind() != reflect.String {
+		return fmt.Errorf("unsupported map key type %s", fv.Type().Key())
+	}
+
+	keys := fv.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+	for _, k := range keys {
+		if err := marshalValue(fv.MapIndex(k), withSegment(path, k.String()), style, FormatMulti, uv, codecs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// marshalSlice encodes a slice field. Slices of scalars default to the
+// repeated "k=v1&k=v2" form (format=multi); format=csv/ssv/pipes instead
+// joins every encoded element into a single delimited value. Slices of
+// structs/maps ignore format and always get an indexed key per element, e.g.
+// "items[0].name=foo&items[1].name=bar".
+func marshalSlice(fv reflect.Value, path []string, style Style, format CollectionFormat, uv url.Values, codecs map[reflect.Type]Codec) error {
+	elemType := fv.Type().Elem()
+
+	if isScalarElem(elemType, codecs) {
+		name := joinPath(path, style)
+
+		delim, delimited := format.delimiter()
+		if !delimited {
+			for i := 0; i < fv.Len(); i++ {
+				strs, err := encodeScalar(fv.Index(i), codecs)
 				if err != nil {
-					return uv, err
+					return err
 				}
 
-				uv.Add(name, s)
+				for _, s := range strs {
+					uv.Add(name, s)
+				}
 			}
-		} else {
-			s, err := toString(fv)
+
+			return nil
+		}
+
+		var parts []string
+		for i := 0; i < fv.Len(); i++ {
+			strs, err := encodeScalar(fv.Index(i), codecs)
 			if err != nil {
-				return uv, err
+				return err
 			}
 
-			uv.Set(name, s)
+			parts = append(parts, strs...)
 		}
+
+		uv.Set(name, joinDelimited(parts, delim))
+		return nil
 	}
 
-	return uv, nil
+	for i := 0; i < fv.Len(); i++ {
+		if err := marshalValue(fv.Index(i), withSegment(path, strconv.Itoa(i)), style, FormatMulti, uv, codecs); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
-// Unmarshal populates dst (pointer to struct) from url.Values.
+// Unmarshal populates dst (pointer to struct) from url.Values, then runs any
+// validation tag constraints (required, min, max, minlen, maxlen, oneof,
+// pattern) declared on dst's fields. Violations are returned together as a
+// *ValidationError. It is equivalent to UnmarshalWith(values, out, Options{}).
 func Unmarshal(values url.Values, out any) error {
+	return UnmarshalWith(values, out, Options{})
+}
+
+// UnmarshalWith behaves like Unmarshal. Nested keys are accepted in either
+// dotted ("address.city") or bracketed ("address[city]") notation regardless
+// of opts, since the input isn't ours to dictate the shape of; opts is
+// reserved for decode-time behaviour that does need a default, such as
+// codec selection.
+func UnmarshalWith(values url.Values, out any, opts Options) error {
+	// See the matching comment in MarshalWith: generated code knows nothing
+	// about opts.Codecs, so a per-call override falls back to reflection.
+	if u, ok := out.(QueryParamsUnmarshaler); ok && len(opts.Codecs) == 0 {
+		if err := u.UnmarshalQueryParams(values); err != nil {
+			return err
+		}
+	} else {
+		v, err := ptr.EnforcePtr(out)
+		if err != nil {
+			return err
+		}
+
+		if v.Kind() != reflect.Struct {
+			return fmt.Errorf("expected struct but got %v", v.Type())
+		}
+
+		ctx := newDecodeContext(values, opts.Codecs)
+		if err := unmarshalStruct(v, nil, ctx); err != nil {
+			return err
+		}
+	}
+
 	v, err := ptr.EnforcePtr(out)
 	if err != nil {
 		return err
 	}
 
-	if v.Kind() != reflect.Struct {
-		return fmt.Errorf("expected struct but got %v", v.Type())
+	errs := map[string]error{}
+	if err := validateStruct(v, nil, errs); err != nil {
+		return err
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+
+	return nil
+}
+
+// pathEntry is a url.Values key, pre-split into path segments so repeated
+// lookups during the walk don't re-parse it.
+type pathEntry struct {
+	key      string
+	segments []string
+}
+
+// decodeContext indexes the incoming url.Values by parsed key path so the
+// struct walker can look up exact matches and enumerate map keys/slice
+// indices present under a given prefix.
+type decodeContext struct {
+	values  url.Values
+	entries []pathEntry
+	codecs  map[reflect.Type]Codec
+}
+
+func newDecodeContext(values url.Values, codecs map[reflect.Type]Codec) *decodeContext {
+	entries := make([]pathEntry, 0, len(values))
+	for k := range values {
+		entries = append(entries, pathEntry{key: k, segments: parseKeyPath(k)})
 	}
 
-	t := v.Type()
+	return &decodeContext{values: values, entries: entries, codecs: codecs}
+}
 
-	for i := 0; i < t.NumField(); i++ {
-		sf := t.Field(i)
-		name, _ := parseTag(sf.Tag.Get(structTagName))
+// lookup returns the raw values for an exact path match, e.g. ["address",
+// "city"] matches both "address.city" and "address[city]".
+func (c *decodeContext) lookup(path []string) ([]string, bool) {
+	for _, e := range c.entries {
+		if segmentsEqual(e.segments, path) {
+			return c.values[e.key], true
+		}
+	}
 
-		if name == "-" {
+	return nil, false
+}
+
+// childKeys returns the distinct next path segment seen under prefix across
+// all incoming keys, sorted for determinism.
+func (c *decodeContext) childKeys(prefix []string) []string {
+	seen := map[string]bool{}
+	var out []string
+
+	for _, e := range c.entries {
+		if len(e.segments) <= len(prefix) || !segmentsEqual(e.segments[:len(prefix)], prefix) {
 			continue
 		}
 
-		if name == "" {
-			name = strings.ToLower(sf.Name[:1]) + sf.Name[1:]
+		seg := e.segments[len(prefix)]
+		if !seen[seg] {
+			seen[seg] = true
+			out = append(out, seg)
 		}
+	}
 
-		strs, ok := values[name]
-		if !ok || len(strs) == 0 {
+	sort.Strings(out)
+	return out
+}
+
+// childIndices is childKeys filtered to integer segments, sorted
+// numerically. Both sparse ("items[0]", "items[7]") and contiguous indices
+// are accepted; the result order is the resulting slice's order.
+func (c *decodeContext) childIndices(prefix []string) []int {
+	segs := c.childKeys(prefix)
+	indices := make([]int, 0, len(segs))
+
+	for _, s := range segs {
+		i, err := strconv.Atoi(s)
+		if err != nil {
 			continue
 		}
 
-		fv := v.Field(i)
+		indices = append(indices, i)
+	}
+
+	sort.Ints(indices)
+	return indices
+}
+
+func unmarshalStruct(v reflect.Value, path []string, ctx *decodeContext) error {
+	meta, err := metaFor(v.Type())
+	if err != nil {
+		return err
+	}
+
+	for _, fm := range meta.fields {
+		name := fm.unmarshalName()
+
+		fv := v.Field(fm.index)
 		if !fv.CanSet() {
 			continue
 		}
 
-		// Handle slices
-		if fv.Kind() == reflect.Slice {
-			sliceType := fv.Type().Elem()
-			newSlice := reflect.MakeSlice(fv.Type(), 0, len(strs))
+		if err := unmarshalValue(fv, withSegment(path, name), fm.format, ctx); err != nil {
+			return fmt.Errorf("field %q: %w", name, err)
+		}
+	}
 
-			for _, s := range strs {
-				elem := reflect.New(sliceType).Elem()
+	return nil
+}
 
-				if err := setFromString(elem, s); err != nil {
-					return fmt.Errorf("field %q: %w", name, err)
-				}
+// unmarshalValue decodes the value addressed by path into fv, recursing into
+// structs, maps, and slices as needed. format only affects fv when it's a
+// scalar slice; it's ignored otherwise.
+func unmarshalValue(fv reflect.Value, path []string, format CollectionFormat, ctx *decodeContext) error {
+	if _, ok := lookupCodec(fv.Type(), ctx.codecs); !ok {
+		if u, ok := implementsUnmarshaler(fv); ok {
+			strs, ok := ctx.lookup(path)
+			if !ok || len(strs) == 0 {
+				return nil
+			}
+
+			return u.UnmarshalQueryParam(strs[0])
+		}
 
-				newSlice = reflect.Append(newSlice, elem)
+		switch fv.Kind() {
+		case reflect.Struct:
+			if _, ok := fv.Interface().(time.Time); !ok {
+				return unmarshalStruct(fv, path, ctx)
 			}
 
-			fv.Set(newSlice)
-			continue
+		case reflect.Map:
+			return unmarshalMap(fv, path, ctx)
+
+		case reflect.Slice:
+			return unmarshalSlice(fv, path, format, ctx)
 		}
+	}
 
-		// Single value
-		if err := setFromString(fv, strs[0]); err != nil {
-			return fmt.Errorf("field %q: %w", name, err)
+	strs, ok := ctx.lookup(path)
+	if !ok {
+		return nil
+	}
+
+	return decodeScalar(fv, strs, ctx.codecs)
+}
+
+func unmarshalMap(fv reflect.Value, path []string, ctx *decodeContext) error {
+	if fv.Type().Key().Kind() != reflect.String {
+		return fmt.Errorf("unsupported map key type %s", fv.Type().Key())
+	}
+
+	keys := ctx.childKeys(path)
+	if len(keys) == 0 {
+		return nil
+	}
+
+	elemType := fv.Type().Elem()
+	m := reflect.MakeMapWithSize(fv.Type(), len(keys))
+
+	for _, k := range keys {
+		elem := reflect.New(elemType).Elem()
+		if err := unmarshalValue(elem, withSegment(path, k), FormatMulti, ctx); err != nil {
+			return err
+		}
+
+		m.SetMapIndex(reflect.ValueOf(k).Convert(fv.Type().Key()), elem)
+	}
+
+	fv.Set(m)
+	return nil
+}
+
+// unmarshalSlice decodes a scalar slice. For format=csv/ssv/pipes every raw
+// value is split on the format's delimiter, so such a field accepts classic
+// repeated keys ("tags=a&tags=b"), a delimited single value ("tags=a,b"), or
+// a mix of both ("tags=a,b&tags=c"); with format=multi each raw value is one
+// element and is not split. Struct/map elements
+// ignore format and use the indexed form instead ("items[0].name=foo");
+// sparse indices are compacted in ascending order.
+func unmarshalSlice(fv reflect.Value, path []string, format CollectionFormat, ctx *decodeContext) error {
+	elemType := fv.Type().Elem()
+
+	if isScalarElem(elemType, ctx.codecs) {
+		strs, ok := ctx.lookup(path)
+		if !ok {
+			return nil
+		}
+
+		delim, delimited := format.delimiter()
+
+		var flat []string
+		for _, s := range strs {
+			if delimited {
+				flat = append(flat, splitDelimited(s, delim)...)
+			} else {
+				flat = append(flat, s)
+			}
+		}
+
+		newSlice := reflect.MakeSlice(fv.Type(), 0, len(flat))
+		for _, s := range flat {
+			elem := reflect.New(elemType).Elem()
+			if err := decodeScalar(elem, []string{s}, ctx.codecs); err != nil {
+				return err
+			}
+
+			newSlice = reflect.Append(newSlice, elem)
+		}
+
+		fv.Set(newSlice)
+		return nil
+	}
+
+	indices := ctx.childIndices(path)
+	if len(indices) == 0 {
+		return nil
+	}
+
+	newSlice := reflect.MakeSlice(fv.Type(), 0, len(indices))
+	for _, idx := range indices {
+		elem := reflect.New(elemType).Elem()
+		if err := unmarshalValue(elem, withSegment(path, strconv.Itoa(idx)), FormatMulti, ctx); err != nil {
+			return err
 		}
+
+		newSlice = reflect.Append(newSlice, elem)
 	}
+
+	fv.Set(newSlice)
 	return nil
 }
 
-func parseTag(tag string) (string, bool) {
+// withSegment returns a new path with seg appended, never aliasing path's
+// backing array so callers can safely reuse path across sibling fields.
+func withSegment(path []string, seg string) []string {
+	next := make([]string, len(path)+1)
+	copy(next, path)
+	next[len(path)] = seg
+	return next
+}
+
+// joinPath renders a path as a query key using style for every segment past
+// the first, e.g. ["items", "0", "name"] -> "items[0].name" (StyleDot) or
+// "items[0][name]" (StyleBracket). Slice/array indices always use brackets
+// regardless of style.
+func joinPath(path []string, style Style) string {
+	if len(path) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(path[0])
+
+	for _, seg := range path[1:] {
+		if style == StyleBracket || isIndexSegment(seg) {
+			b.WriteByte('[')
+			b.WriteString(seg)
+			b.WriteByte(']')
+		} else {
+			b.WriteByte('.')
+			b.WriteString(seg)
+		}
+	}
+
+	return b.String()
+}
+
+// parseKeyPath splits an incoming query key into path segments, accepting
+// both dotted and bracketed notation: "items[0].name" and "items.0.name"
+// both yield ["items", "0", "name"].
+func parseKeyPath(key string) []string {
+	var segs []string
+	i, n := 0, len(key)
+
+	for i < n {
+		if key[i] == '[' {
+			j := strings.IndexByte(key[i:], ']')
+			if j < 0 {
+				segs = append(segs, key[i+1:])
+				break
+			}
+
+			segs = append(segs, key[i+1:i+j])
+			i += j + 1
+		} else {
+			j := i
+			for j < n && key[j] != '.' && key[j] != '[' {
+				j++
+			}
+
+			segs = append(segs, key[i:j])
+			i = j
+		}
+
+		if i < n && key[i] == '.' {
+			i++
+		}
+	}
+
+	return segs
+}
+
+func segmentsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isIndexSegment(seg string) bool {
+	_, err := strconv.Atoi(seg)
+	return err == nil
+}
+
+// isScalarElem reports whether t should be encoded as a single query value
+// (and, for slices, per the field's collection format) rather than walked
+// field-by-field. A
+// type with a registered Codec (global or per-call override) is always
+// scalar, even if its Kind is Struct/Map/Slice, since the Codec is what
+// knows how to turn it into string(s), not the struct walker.
+func isScalarElem(t reflect.Type, codecs map[reflect.Type]Codec) bool {
+	if _, ok := lookupCodec(t, codecs); ok {
+		return true
+	}
+
+	if t == timeType {
+		return true
+	}
+
+	if t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType) {
+		return true
+	}
+
+	if t.Implements(unmarshalerType) || reflect.PointerTo(t).Implements(unmarshalerType) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice:
+		return false
+	default:
+		return true
+	}
+}
+
+// parseTag parses a `query` struct tag of the form
+// "name,omitzero,style=bracket" into its name, whether the zero value should
+// be omitted on encode, and an optional per-field style override.
+func parseTag(tag string) (name string, omitzero bool, styleOverride *Style) {
 	if tag == "" {
-		return "", false
+		return "", false, nil
 	}
 
-	parts := strings.Split(tag, ",")
-	name := parts[0]
-	omitzero := false
+	parts := splitTagOptions(tag)
+	name = parts[0]
+
 	for _, p := range parts[1:] {
-		if p == "omitzero" {
+		switch {
+		case p == "omitzero":
 			omitzero = true
+
+		case strings.HasPrefix(p, "style="):
+			switch strings.TrimPrefix(p, "style=") {
+			case "bracket":
+				s := StyleBracket
+				styleOverride = &s
+			case "dot":
+				s := StyleDot
+				styleOverride = &s
+			}
 		}
 	}
 
-	return name, omitzero
+	return name, omitzero, styleOverride
 }
 
 func toString(v reflect.Value) (string, error) {
@@ -0,0 +1,101 @@
+package queryparams
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+type address struct {
+	City string `query:"city"`
+	Zip  string `query:"zip"`
+}
+
+type item struct {
+	Name string `query:"name"`
+}
+
+type payload struct {
+	Name    string            `query:"name"`
+	Address address           `query:"address"`
+	Labels  map[string]string `query:"labels"`
+	Items   []item            `query:"items"`
+}
+
+func TestMarshalUnmarshalNestedRoundTrip(t *testing.T) {
+	in := payload{
+		Name:    "acme",
+		Address: address{City: "NYC", Zip: "10001"},
+		Labels:  map[string]string{"env": "prod", "tier": "gold"},
+		Items:   []item{{Name: "foo"}, {Name: "bar"}},
+	}
+
+	values, err := Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	if got, want := values.Get("address.city"), "NYC"; got != want {
+		t.Errorf("address.city = %q, want %q", got, want)
+	}
+	if got, want := values.Get("items[0].name"), "foo"; got != want {
+		t.Errorf("items[0].name = %q, want %q", got, want)
+	}
+	if got, want := values.Get("labels.env"), "prod"; got != want {
+		t.Errorf("labels.env = %q, want %q", got, want)
+	}
+
+	var out payload
+	if err := Unmarshal(values, &out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("round trip mismatch:\n in  = %+v\n out = %+v", in, out)
+	}
+}
+
+func TestMarshalWithBracketStyle(t *testing.T) {
+	in := payload{Address: address{City: "NYC"}}
+
+	values, err := MarshalWith(in, Options{Style: StyleBracket})
+	if err != nil {
+		t.Fatalf("MarshalWith: %v", err)
+	}
+
+	if got, want := values.Get("address[city]"), "NYC"; got != want {
+		t.Errorf("address[city] = %q, want %q", got, want)
+	}
+}
+
+func TestUnmarshalAcceptsDottedAndBracketedNotation(t *testing.T) {
+	var dotted, bracketed payload
+
+	if err := Unmarshal(url.Values{"address.city": {"NYC"}}, &dotted); err != nil {
+		t.Fatalf("Unmarshal dotted: %v", err)
+	}
+	if err := Unmarshal(url.Values{"address[city]": {"NYC"}}, &bracketed); err != nil {
+		t.Fatalf("Unmarshal bracketed: %v", err)
+	}
+
+	if dotted.Address.City != "NYC" || bracketed.Address.City != "NYC" {
+		t.Errorf("got dotted=%q bracketed=%q, want both NYC", dotted.Address.City, bracketed.Address.City)
+	}
+}
+
+func TestUnmarshalCompactsSparseSliceIndices(t *testing.T) {
+	values := url.Values{
+		"items[0].name": {"first"},
+		"items[7].name": {"second"},
+	}
+
+	var out payload
+	if err := Unmarshal(values, &out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	want := []item{{Name: "first"}, {Name: "second"}}
+	if !reflect.DeepEqual(out.Items, want) {
+		t.Errorf("Items = %+v, want %+v", out.Items, want)
+	}
+}
@@ -0,0 +1,269 @@
+package queryparams
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidationError is returned by Unmarshal/UnmarshalWith when one or more
+// fields fail their `query` tag constraints (required, min, max, minlen,
+// maxlen, oneof, pattern). Errors is keyed by the field's dotted path (e.g.
+// "address.zip") so an HTTP handler can render a 400 with a field->message
+// map.
+type ValidationError struct {
+	Errors map[string]error
+}
+
+func (e *ValidationError) Error() string {
+	names := sortedKeys(e.Errors)
+	msgs := make([]string, 0, len(names))
+	for _, name := range names {
+		msgs = append(msgs, fmt.Sprintf("%s: %v", name, e.Errors[name]))
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap exposes each field error individually so callers can errors.Is/As
+// against a specific violation.
+func (e *ValidationError) Unwrap() []error {
+	names := sortedKeys(e.Errors)
+	errs := make([]error, 0, len(names))
+	for _, name := range names {
+		errs = append(errs, e.Errors[name])
+	}
+
+	return errs
+}
+
+func sortedKeys(m map[string]error) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// fieldRule holds the parsed validation constraints for a single field.
+type fieldRule struct {
+	required bool
+	min, max *float64
+	minLen   *int
+	maxLen   *int
+	oneOf    []string
+	pattern  *regexp.Regexp
+}
+
+// parseFieldRule parses the validation options out of a `query` struct tag,
+// e.g. "page,required,min=1,max=100".
+func parseFieldRule(tag string) (fieldRule, error) {
+	var r fieldRule
+	if tag == "" {
+		return r, nil
+	}
+
+	parts := splitTagOptions(tag)
+	for _, p := range parts[1:] {
+		switch {
+		case p == "required":
+			r.required = true
+
+		case strings.HasPrefix(p, "min="):
+			v, err := strconv.ParseFloat(strings.TrimPrefix(p, "min="), 64)
+			if err != nil {
+				return r, fmt.Errorf("invalid min: %w", err)
+			}
+			r.min = &v
+
+		case strings.HasPrefix(p, "max="):
+			v, err := strconv.ParseFloat(strings.TrimPrefix(p, "max="), 64)
+			if err != nil {
+				return r, fmt.Errorf("invalid max: %w", err)
+			}
+			r.max = &v
+
+		case strings.HasPrefix(p, "minlen="):
+			n, err := strconv.Atoi(strings.TrimPrefix(p, "minlen="))
+			if err != nil {
+				return r, fmt.Errorf("invalid minlen: %w", err)
+			}
+			r.minLen = &n
+
+		case strings.HasPrefix(p, "maxlen="):
+			n, err := strconv.Atoi(strings.TrimPrefix(p, "maxlen="))
+			if err != nil {
+				return r, fmt.Errorf("invalid maxlen: %w", err)
+			}
+			r.maxLen = &n
+
+		case strings.HasPrefix(p, "oneof="):
+			r.oneOf = strings.Split(strings.TrimPrefix(p, "oneof="), "|")
+
+		case strings.HasPrefix(p, "pattern="):
+			re, err := regexp.Compile(strings.TrimPrefix(p, "pattern="))
+			if err != nil {
+				return r, fmt.Errorf("invalid pattern: %w", err)
+			}
+			r.pattern = re
+		}
+	}
+
+	return r, nil
+}
+
+// validateStruct checks v's fields against their cached rules, recording a
+// message per violation under its dotted path in errs. Nested structs, and
+// structs reached through a map or slice field, are walked too (mirroring
+// unmarshalStruct's shapes), but a field that already failed isn't recursed
+// into.
+func validateStruct(v reflect.Value, path []string, errs map[string]error) error {
+	meta, err := metaFor(v.Type())
+	if err != nil {
+		return err
+	}
+
+	for _, fm := range meta.fields {
+		fv := v.Field(fm.index)
+		name := fm.unmarshalName()
+		fullName := strings.Join(withSegment(path, name), ".")
+
+		if msg := checkRule(fv, fm.rule); msg != "" {
+			errs[fullName] = errors.New(msg)
+			continue
+		}
+
+		switch {
+		case fv.Kind() == reflect.Struct:
+			if _, ok := fv.Interface().(time.Time); !ok {
+				if err := validateStruct(fv, withSegment(path, name), errs); err != nil {
+					return err
+				}
+			}
+
+		case fv.Kind() == reflect.Map && fv.Type().Elem().Kind() == reflect.Struct && fv.Type().Elem() != timeType:
+			if err := validateMapElems(fv, withSegment(path, name), errs); err != nil {
+				return err
+			}
+
+		case fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() == reflect.Struct && fv.Type().Elem() != timeType:
+			if err := validateSliceElems(fv, withSegment(path, name), errs); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateMapElems walks a map[string]T field's struct-typed values, each
+// keyed by its map key under path, mirroring unmarshalMap's walk.
+func validateMapElems(fv reflect.Value, path []string, errs map[string]error) error {
+	keys := fv.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+	for _, k := range keys {
+		if err := validateStruct(fv.MapIndex(k), withSegment(path, k.String()), errs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateSliceElems walks a []T field's struct elements, each keyed by its
+// index under path, mirroring unmarshalSlice's walk.
+func validateSliceElems(fv reflect.Value, path []string, errs map[string]error) error {
+	for i := 0; i < fv.Len(); i++ {
+		if err := validateStruct(fv.Index(i), withSegment(path, strconv.Itoa(i)), errs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkRule returns a human-readable violation message, or "" if fv
+// satisfies r. Range/length/oneof/pattern checks are skipped on an optional
+// field's zero value; required already covers that case.
+func checkRule(fv reflect.Value, r fieldRule) string {
+	if r.required && fv.IsZero() {
+		return "is required"
+	}
+
+	if fv.IsZero() {
+		return ""
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		s := fv.String()
+		if r.minLen != nil && len(s) < *r.minLen {
+			return fmt.Sprintf("must be at least %d characters", *r.minLen)
+		}
+		if r.maxLen != nil && len(s) > *r.maxLen {
+			return fmt.Sprintf("must be at most %d characters", *r.maxLen)
+		}
+		if len(r.oneOf) > 0 && !containsString(r.oneOf, s) {
+			return fmt.Sprintf("must be one of %s", strings.Join(r.oneOf, ", "))
+		}
+		if r.pattern != nil && !r.pattern.MatchString(s) {
+			return fmt.Sprintf("must match pattern %s", r.pattern.String())
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := float64(fv.Int())
+		if r.min != nil && n < *r.min {
+			return fmt.Sprintf("must be >= %v", *r.min)
+		}
+		if r.max != nil && n > *r.max {
+			return fmt.Sprintf("must be <= %v", *r.max)
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n := float64(fv.Uint())
+		if r.min != nil && n < *r.min {
+			return fmt.Sprintf("must be >= %v", *r.min)
+		}
+		if r.max != nil && n > *r.max {
+			return fmt.Sprintf("must be <= %v", *r.max)
+		}
+
+	case reflect.Float32, reflect.Float64:
+		n := fv.Float()
+		if r.min != nil && n < *r.min {
+			return fmt.Sprintf("must be >= %v", *r.min)
+		}
+		if r.max != nil && n > *r.max {
+			return fmt.Sprintf("must be <= %v", *r.max)
+		}
+
+	case reflect.Slice:
+		n := fv.Len()
+		if r.minLen != nil && n < *r.minLen {
+			return fmt.Sprintf("must have at least %d items", *r.minLen)
+		}
+		if r.maxLen != nil && n > *r.maxLen {
+			return fmt.Sprintf("must have at most %d items", *r.maxLen)
+		}
+	}
+
+	return ""
+}
+
+func containsString(haystack []string, needle string) bool {
+	for _, s := range haystack {
+		if s == needle {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,163 @@
+package queryparams
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+)
+
+type codeForm struct {
+	Code string `query:"code,pattern=^[A-Z]{2,4}$"`
+}
+
+func TestPatternWithCommaQuantifier(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    string
+		wantErr bool
+	}{
+		{"matches within quantifier range", "ABCD", false},
+		{"too short for quantifier", "A", true},
+		{"wrong case", "abcd", true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var out codeForm
+			err := Unmarshal(url.Values{"code": {tc.code}}, &out)
+
+			if tc.wantErr {
+				var verr *ValidationError
+				if !errors.As(err, &verr) {
+					t.Fatalf("Unmarshal(%q) error = %v, want a *ValidationError", tc.code, err)
+				}
+				if _, ok := verr.Errors["code"]; !ok {
+					t.Fatalf("ValidationError.Errors = %v, want a \"code\" entry", verr.Errors)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unmarshal(%q): %v", tc.code, err)
+			}
+			if out.Code != tc.code {
+				t.Errorf("Code = %q, want %q", out.Code, tc.code)
+			}
+		})
+	}
+}
+
+type codeWithOmitzeroForm struct {
+	Code string `query:"code,pattern=^[A-Z]{2,4}$,omitzero"`
+}
+
+func TestPatternFollowedByFurtherOptions(t *testing.T) {
+	var out codeWithOmitzeroForm
+	if err := Unmarshal(url.Values{"code": {"ABCD"}}, &out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if out.Code != "ABCD" {
+		t.Errorf("Code = %q, want %q", out.Code, "ABCD")
+	}
+
+	values, err := Marshal(codeWithOmitzeroForm{})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if _, ok := values["code"]; ok {
+		t.Errorf("omitzero after pattern= was not honored, got %v", values)
+	}
+}
+
+type signupForm struct {
+	Email string `query:"email,required"`
+	Age   int    `query:"age,min=18,max=120"`
+	Plan  string `query:"plan,oneof=free|pro|enterprise"`
+}
+
+func TestValidationConstraintsAggregateErrors(t *testing.T) {
+	var out signupForm
+	err := Unmarshal(url.Values{"age": {"10"}, "plan": {"deluxe"}}, &out)
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Unmarshal error = %v, want a *ValidationError", err)
+	}
+
+	for _, field := range []string{"email", "age", "plan"} {
+		if _, ok := verr.Errors[field]; !ok {
+			t.Errorf("ValidationError.Errors missing %q, got %v", field, verr.Errors)
+		}
+	}
+
+	unwrapped := verr.Unwrap()
+	if len(unwrapped) != len(verr.Errors) {
+		t.Errorf("Unwrap() returned %d errors, want %d", len(unwrapped), len(verr.Errors))
+	}
+}
+
+type lineItem struct {
+	Name string `query:"name,required"`
+}
+
+type orderForm struct {
+	Items []lineItem          `query:"items"`
+	Notes map[string]lineItem `query:"notes"`
+}
+
+func TestValidationRecursesIntoSliceAndMapOfStructs(t *testing.T) {
+	values := url.Values{
+		"items[0].name": {""},
+		"items[1].name": {"widget"},
+		"notes.a.name":  {""},
+	}
+
+	var out orderForm
+	err := Unmarshal(values, &out)
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Unmarshal error = %v, want a *ValidationError", err)
+	}
+
+	for _, field := range []string{"items.0.name", "notes.a.name"} {
+		if _, ok := verr.Errors[field]; !ok {
+			t.Errorf("ValidationError.Errors missing %q, got %v", field, verr.Errors)
+		}
+	}
+	if _, ok := verr.Errors["items.1.name"]; ok {
+		t.Errorf("ValidationError.Errors has %q, want it absent since items[1].name is set", "items.1.name")
+	}
+}
+
+type scheduleForm struct {
+	Slots map[string]time.Time `query:"slots"`
+}
+
+func TestValidationTreatsMapOfTimeAsScalarNotStruct(t *testing.T) {
+	var out scheduleForm
+	err := Unmarshal(url.Values{"slots.morning": {"2024-01-02T03:04:05Z"}}, &out)
+	if err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	want, _ := time.Parse(time.RFC3339, "2024-01-02T03:04:05Z")
+	if !out.Slots["morning"].Equal(want) {
+		t.Errorf("Slots[morning] = %v, want %v", out.Slots["morning"], want)
+	}
+}
+
+func TestValidationPassesWithinConstraints(t *testing.T) {
+	var out signupForm
+	err := Unmarshal(url.Values{
+		"email": {"a@b.com"},
+		"age":   {"30"},
+		"plan":  {"pro"},
+	}, &out)
+
+	if err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+}